@@ -0,0 +1,31 @@
+package tokenauth
+
+import (
+	"fmt"
+	"strings"
+)
+
+// formatChallenge renders a single WWW-Authenticate challenge per RFC 7235
+// section 4.1, e.g.:
+//
+//	Bearer realm="api", error="invalid_token", error_description="token expired"
+//
+// params are additional auth-params as ordered key/value pairs (e.g.
+// "error", "invalid_token", "scope", "a b"); empty values are omitted.
+// AuthTokenMiddleware emits one such header per configured scheme so clients
+// can negotiate, similar to how the docker registry client's
+// parseAuthHeader parses the other side of this same format.
+func formatChallenge(scheme, realm string, params ...string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s realm=%q", scheme, realm)
+
+	for i := 0; i+1 < len(params); i += 2 {
+		key, value := params[i], params[i+1]
+		if value == "" {
+			continue
+		}
+		fmt.Fprintf(&b, ", %s=%q", key, value)
+	}
+
+	return b.String()
+}
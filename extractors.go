@@ -0,0 +1,80 @@
+package tokenauth
+
+import (
+	"encoding/base64"
+	"strings"
+
+	"github.com/ant0ine/go-json-rest/rest"
+)
+
+// TokenExtractor attempts to pull a scheme and raw token out of an incoming
+// request. ok is false when this extractor found nothing applicable, which
+// is not itself an error: AuthTokenMiddleware tries the next extractor in
+// its chain in that case.
+type TokenExtractor func(request *rest.Request) (scheme, token string, ok bool)
+
+// QueryTokenExtractor extracts a token from the given query string parameter,
+// e.g. "?access_token=...", for clients that can't set headers. The returned
+// scheme is always the fixed scheme passed in, since there is nothing in the
+// query string to negotiate.
+func QueryTokenExtractor(param string, scheme TokenScheme) TokenExtractor {
+	return func(request *rest.Request) (string, string, bool) {
+		token := request.URL.Query().Get(param)
+		if token == "" {
+			return "", "", false
+		}
+		return string(scheme), token, true
+	}
+}
+
+// HeaderTokenExtractor extracts a token from the Authorization header,
+// preserving whatever scheme the client sent (e.g. "Token" or "Bearer") so
+// AuthTokenMiddleware can dispatch on it.
+func HeaderTokenExtractor() TokenExtractor {
+	return func(request *rest.Request) (string, string, bool) {
+		authHeader := request.Header.Get("Authorization")
+		if authHeader == "" {
+			return "", "", false
+		}
+		scheme, token, err := decodeAuthHeader(authHeader)
+		if err != nil {
+			return "", "", false
+		}
+		return scheme, token, true
+	}
+}
+
+// CookieTokenExtractor extracts a token from the named cookie, for
+// deployments that authenticate browser sessions rather than API clients.
+func CookieTokenExtractor(name string, scheme TokenScheme) TokenExtractor {
+	return func(request *rest.Request) (string, string, bool) {
+		cookie, err := request.Cookie(name)
+		if err != nil || cookie.Value == "" {
+			return "", "", false
+		}
+		return string(scheme), cookie.Value, true
+	}
+}
+
+// BasicTokenExtractor extracts a token from the password field of an
+// "Authorization: Basic ..." header, as several ecosystem middlewares allow
+// for clients that can only do HTTP Basic auth. The username is ignored.
+func BasicTokenExtractor() TokenExtractor {
+	return func(request *rest.Request) (string, string, bool) {
+		parts := strings.SplitN(request.Header.Get("Authorization"), " ", 2)
+		if len(parts) != 2 || parts[0] != "Basic" {
+			return "", "", false
+		}
+
+		decoded, err := base64.StdEncoding.DecodeString(parts[1])
+		if err != nil {
+			return "", "", false
+		}
+
+		creds := strings.SplitN(string(decoded), ":", 2)
+		if len(creds) != 2 || creds[1] == "" {
+			return "", "", false
+		}
+		return "Basic", creds[1], true
+	}
+}
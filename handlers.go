@@ -0,0 +1,48 @@
+package tokenauth
+
+import (
+	"net/http"
+
+	"github.com/ant0ine/go-json-rest/rest"
+)
+
+// LogoutHandler returns a rest.HandlerFunc that revokes the caller's current
+// token in store, identified via Token(request). Mount it behind
+// AuthTokenMiddleware so the request is already authenticated.
+func LogoutHandler(store TokenStore) rest.HandlerFunc {
+	return func(writer rest.ResponseWriter, request *rest.Request) {
+		raw, err := Token(request)
+		if err != nil {
+			rest.Error(writer, "Bad Request", http.StatusBadRequest)
+			return
+		}
+
+		if err := store.Revoke(raw); err != nil {
+			rest.Error(writer, "Not Found", http.StatusNotFound)
+			return
+		}
+
+		writer.WriteJson(map[string]string{"status": "ok"})
+	}
+}
+
+// RefreshHandler returns a rest.HandlerFunc that rotates the caller's
+// current token in store, identified via Token(request), and responds with
+// the newly issued IssuedToken as JSON.
+func RefreshHandler(store TokenStore) rest.HandlerFunc {
+	return func(writer rest.ResponseWriter, request *rest.Request) {
+		raw, err := Token(request)
+		if err != nil {
+			rest.Error(writer, "Bad Request", http.StatusBadRequest)
+			return
+		}
+
+		newToken, err := store.Rotate(raw)
+		if err != nil {
+			rest.Error(writer, "Not Found", http.StatusNotFound)
+			return
+		}
+
+		writer.WriteJson(newToken)
+	}
+}
@@ -0,0 +1,122 @@
+package tokenauth
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// HashParams tunes the Argon2id cost parameters used by HashArgon2id.
+// The defaults follow current OWASP guidance for argon2id: 46 MiB of
+// memory, a single iteration, and one thread of parallelism.
+type HashParams struct {
+	Memory  uint32 // KiB
+	Time    uint32
+	Threads uint8
+	SaltLen uint32
+	KeyLen  uint32
+}
+
+// DefaultHashParams are the HashParams used by HashArgon2id when none are given.
+var DefaultHashParams = HashParams{
+	Memory:  46 * 1024,
+	Time:    1,
+	Threads: 1,
+	SaltLen: 8,
+	KeyLen:  16,
+}
+
+// HashArgon2id derives a PHC-formatted argon2id hash of token, suitable for
+// storage in place of the token itself, using DefaultHashParams. Use
+// HashArgon2idWithParams to tune the cost parameters.
+func HashArgon2id(token string) (string, error) {
+	return HashArgon2idWithParams(token, DefaultHashParams)
+}
+
+// HashArgon2idWithParams is HashArgon2id with configurable cost parameters.
+func HashArgon2idWithParams(token string, params HashParams) (string, error) {
+	salt := make([]byte, params.SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(token), salt, params.Time, params.Memory, params.Threads, params.KeyLen)
+
+	return fmt.Sprintf(
+		"$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version,
+		params.Memory, params.Time, params.Threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// VerifyHash reports whether token matches stored, which may be either a PHC
+// argon2id string produced by HashArgon2id or a legacy hash produced by the
+// deprecated Hash function. This lets deployments migrate stored hashes
+// lazily: verify against whatever is on record, then re-hash with
+// HashArgon2id on next successful login.
+func VerifyHash(token, stored string) bool {
+	if !strings.HasPrefix(stored, "$") {
+		return Equal(Hash(token), stored)
+	}
+
+	params, salt, key, err := decodePHC(stored)
+	if err != nil {
+		return false
+	}
+
+	candidate := argon2.IDKey([]byte(token), salt, params.Time, params.Memory, params.Threads, uint32(len(key)))
+	return subtle.ConstantTimeCompare(candidate, key) == 1
+}
+
+// decodePHC parses a "$argon2id$v=19$m=...,t=...,p=...$salt$hash" string.
+func decodePHC(stored string) (HashParams, []byte, []byte, error) {
+	fields := strings.Split(stored, "$")
+	// fields[0] is empty (string starts with "$"); the format has 6 parts total.
+	if len(fields) != 6 || fields[1] != "argon2id" {
+		return HashParams{}, nil, nil, errors.New("tokenauth: not an argon2id PHC string")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(fields[2], "v=%d", &version); err != nil {
+		return HashParams{}, nil, nil, errors.New("tokenauth: invalid argon2id version field")
+	}
+	if version != argon2.Version {
+		return HashParams{}, nil, nil, errors.New("tokenauth: unsupported argon2id version")
+	}
+
+	var params HashParams
+	if _, err := fmt.Sscanf(fields[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Time, &params.Threads); err != nil {
+		return HashParams{}, nil, nil, errors.New("tokenauth: invalid argon2id params field")
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(fields[4])
+	if err != nil {
+		return HashParams{}, nil, nil, errors.New("tokenauth: invalid argon2id salt")
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(fields[5])
+	if err != nil {
+		return HashParams{}, nil, nil, errors.New("tokenauth: invalid argon2id hash")
+	}
+
+	return params, salt, key, nil
+}
+
+// Hash applies a simple MD5 hash over a token.
+//
+// Deprecated: MD5 is unsuitable for hashing tokens because it's fast enough
+// to brute-force offline if the stored hashes leak. Use HashArgon2id instead;
+// VerifyHash still accepts hashes produced by Hash so stored values can be
+// migrated lazily.
+func Hash(token string) string {
+	hashed := md5.Sum([]byte(token))
+	return base64.URLEncoding.EncodeToString(hashed[:])
+}
@@ -0,0 +1,31 @@
+package tokenauth
+
+import "testing"
+
+func TestVerifyHashArgon2idRoundTrip(t *testing.T) {
+	token := "s3cr3t-token"
+
+	stored, err := HashArgon2id(token)
+	if err != nil {
+		t.Fatalf("HashArgon2id: %v", err)
+	}
+
+	if !VerifyHash(token, stored) {
+		t.Fatalf("expected VerifyHash to accept the matching token")
+	}
+	if VerifyHash("wrong-token", stored) {
+		t.Fatalf("expected VerifyHash to reject a non-matching token")
+	}
+}
+
+func TestVerifyHashLegacyMD5Fallback(t *testing.T) {
+	token := "legacy-token"
+	stored := Hash(token) // no "$" prefix: the pre-Argon2id format
+
+	if !VerifyHash(token, stored) {
+		t.Fatalf("expected VerifyHash to accept a legacy MD5 hash")
+	}
+	if VerifyHash("wrong-token", stored) {
+		t.Fatalf("expected VerifyHash to reject a non-matching legacy hash")
+	}
+}
@@ -0,0 +1,57 @@
+package tokenauth
+
+import (
+	"testing"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+)
+
+func TestAuthenticateJWTEnforcesIssuerAndAudience(t *testing.T) {
+	secret := []byte("test-secret")
+	keyFunc := func(token *jwt.Token) (interface{}, error) {
+		return secret, nil
+	}
+
+	sign := func(claims jwt.StandardClaims) string {
+		signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+		if err != nil {
+			t.Fatalf("SignedString: %v", err)
+		}
+		return signed
+	}
+
+	mw := &AuthTokenMiddleware{
+		JWTKeyFunc: keyFunc,
+		Claims: func() jwt.Claims {
+			return &jwt.StandardClaims{}
+		},
+		AuthenticatorClaims: func(claims jwt.Claims) string {
+			return "user-1"
+		},
+		Issuer:   "trusted-issuer",
+		Audience: "trusted-audience",
+	}
+
+	base := jwt.StandardClaims{
+		ExpiresAt: time.Now().Add(time.Hour).Unix(),
+		Issuer:    "trusted-issuer",
+		Audience:  "trusted-audience",
+	}
+
+	if userID := mw.authenticateJWT(sign(base)); userID != "user-1" {
+		t.Fatalf("expected a token with matching iss/aud to authenticate, got userID=%q", userID)
+	}
+
+	wrongIssuer := base
+	wrongIssuer.Issuer = "attacker-issuer"
+	if userID := mw.authenticateJWT(sign(wrongIssuer)); userID != "" {
+		t.Fatalf("expected a token with the wrong issuer to be rejected, got userID=%q", userID)
+	}
+
+	wrongAudience := base
+	wrongAudience.Audience = "attacker-audience"
+	if userID := mw.authenticateJWT(sign(wrongAudience)); userID != "" {
+		t.Fatalf("expected a token with the wrong audience to be rejected, got userID=%q", userID)
+	}
+}
@@ -0,0 +1,108 @@
+package tokenauth
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/ant0ine/go-json-rest/rest"
+)
+
+// RoleMode controls how the required roles passed to AuthRoleMiddleware or
+// RequireRoles are matched against the roles a user actually has.
+type RoleMode int
+
+const (
+	// RoleModeOr grants access if the user has at least one of the required roles.
+	RoleModeOr RoleMode = iota
+	// RoleModeAnd grants access only if the user has all of the required roles.
+	RoleModeAnd
+)
+
+// RoleProvider resolves the roles held by an authenticated user, e.g. via a
+// lookup against a users/roles table.
+type RoleProvider func(userID string) ([]string, error)
+
+// AuthRoleMiddleware enforces role-based access control. It must run after
+// AuthTokenMiddleware (or anything else that sets request.Env["REMOTE_USER"]).
+type AuthRoleMiddleware struct {
+	// RoleProvider resolves the roles held by an authenticated user. Required.
+	RoleProvider RoleProvider
+
+	// Mode determines whether Roles is matched as any-of (RoleModeOr, the
+	// default) or all-of (RoleModeAnd).
+	Mode RoleMode
+
+	// Roles are the roles required to access the wrapped handler. Required.
+	Roles []string
+}
+
+// MiddlewareFunc makes AuthRoleMiddleware implement the Middleware interface.
+func (mw *AuthRoleMiddleware) MiddlewareFunc(handler rest.HandlerFunc) rest.HandlerFunc {
+	if mw.RoleProvider == nil {
+		log.Fatal("RoleProvider is required")
+	}
+
+	if len(mw.Roles) == 0 {
+		log.Fatal("Roles is required")
+	}
+
+	return func(writer rest.ResponseWriter, request *rest.Request) {
+		userID, ok := request.Env["REMOTE_USER"].(string)
+		if !ok || userID == "" {
+			rest.Error(writer, "Not Authorized", http.StatusUnauthorized)
+			return
+		}
+
+		roles, err := mw.RoleProvider(userID)
+		if err != nil {
+			rest.Error(writer, "Forbidden", http.StatusForbidden)
+			return
+		}
+		request.Env["REMOTE_USER_ROLES"] = roles
+
+		if !matchRoles(mw.Mode, mw.Roles, roles) {
+			rest.Error(writer, "Forbidden", http.StatusForbidden)
+			return
+		}
+
+		handler(writer, request)
+	}
+}
+
+// RequireRoles builds an AuthRoleMiddleware bound to p that requires roles,
+// matched according to mode, for use alongside AuthTokenMiddleware in a
+// rest.App middleware chain, e.g.:
+//
+//	api.Use(&tokenauth.AuthTokenMiddleware{...})
+//	api.Use(myRoleProvider.RequireRoles(tokenauth.RoleModeOr, "admin", "editor"))
+func (p RoleProvider) RequireRoles(mode RoleMode, roles ...string) *AuthRoleMiddleware {
+	return &AuthRoleMiddleware{
+		RoleProvider: p,
+		Mode:         mode,
+		Roles:        roles,
+	}
+}
+
+func matchRoles(mode RoleMode, required, held []string) bool {
+	heldSet := make(map[string]bool, len(held))
+	for _, r := range held {
+		heldSet[r] = true
+	}
+
+	switch mode {
+	case RoleModeAnd:
+		for _, r := range required {
+			if !heldSet[r] {
+				return false
+			}
+		}
+		return true
+	default: // RoleModeOr
+		for _, r := range required {
+			if heldSet[r] {
+				return true
+			}
+		}
+		return false
+	}
+}
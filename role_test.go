@@ -0,0 +1,26 @@
+package tokenauth
+
+import "testing"
+
+func TestMatchRoles(t *testing.T) {
+	cases := []struct {
+		name     string
+		mode     RoleMode
+		required []string
+		held     []string
+		want     bool
+	}{
+		{"or matches any", RoleModeOr, []string{"admin", "editor"}, []string{"editor"}, true},
+		{"or matches none", RoleModeOr, []string{"admin", "editor"}, []string{"viewer"}, false},
+		{"and requires all", RoleModeAnd, []string{"admin", "editor"}, []string{"admin", "editor", "viewer"}, true},
+		{"and missing one", RoleModeAnd, []string{"admin", "editor"}, []string{"admin"}, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := matchRoles(c.mode, c.required, c.held); got != c.want {
+				t.Fatalf("matchRoles(%v, %v, %v) = %v, want %v", c.mode, c.required, c.held, got, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package tokenauth
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/ant0ine/go-json-rest/rest"
+)
+
+// ScopeProvider resolves the scopes granted to a raw token, for use with
+// RequireScopes when tokens aren't already backed by a TokenStore.
+type ScopeProvider func(token string) ([]string, error)
+
+// Scopes returns the scopes recorded for the current request, either by
+// AuthTokenMiddleware.Store or by RequireScopes, or nil if none were resolved.
+func Scopes(request *rest.Request) []string {
+	scopes, _ := request.Env["TOKEN_SCOPES"].([]string)
+	return scopes
+}
+
+// RequireScopes returns a rest.Middleware enforcing that the caller's token
+// carries every one of scopes, for use behind AuthTokenMiddleware. It reuses
+// request.Env["TOKEN_SCOPES"] if that middleware's Store already populated
+// it; otherwise it resolves the scopes via provider, called with the raw
+// token from Token(request), and caches the result the same way.
+//
+// On rejection it responds 401 with a bare Bearer challenge if no scopes
+// could be resolved at all, or 403 with a
+// WWW-Authenticate: Bearer realm="...", error="insufficient_scope", scope="..."
+// header (RFC 6750 section 3.1) naming the missing scopes otherwise.
+func RequireScopes(realm string, provider ScopeProvider, scopes ...string) rest.Middleware {
+	return &scopeMiddleware{realm: realm, provider: provider, required: scopes}
+}
+
+type scopeMiddleware struct {
+	realm    string
+	provider ScopeProvider
+	required []string
+}
+
+// MiddlewareFunc makes scopeMiddleware implement the Middleware interface.
+func (mw *scopeMiddleware) MiddlewareFunc(handler rest.HandlerFunc) rest.HandlerFunc {
+	return func(writer rest.ResponseWriter, request *rest.Request) {
+		granted := Scopes(request)
+
+		if granted == nil {
+			if mw.provider == nil {
+				mw.unauthorized(writer)
+				return
+			}
+
+			raw, err := Token(request)
+			if err != nil {
+				mw.unauthorized(writer)
+				return
+			}
+
+			granted, err = mw.provider(raw)
+			if err != nil {
+				mw.unauthorized(writer)
+				return
+			}
+			request.Env["TOKEN_SCOPES"] = granted
+		}
+
+		if missing := missingScopes(mw.required, granted); len(missing) > 0 {
+			mw.forbidden(writer, missing)
+			return
+		}
+
+		handler(writer, request)
+	}
+}
+
+func (mw *scopeMiddleware) unauthorized(writer rest.ResponseWriter) {
+	writer.Header().Set("WWW-Authenticate", formatChallenge(string(SchemeBearer), mw.realm))
+	rest.Error(writer, "Not Authorized", http.StatusUnauthorized)
+}
+
+func (mw *scopeMiddleware) forbidden(writer rest.ResponseWriter, missing []string) {
+	challenge := formatChallenge(string(SchemeBearer), mw.realm,
+		"error", "insufficient_scope",
+		"scope", strings.Join(missing, " "),
+	)
+	writer.Header().Set("WWW-Authenticate", challenge)
+	rest.Error(writer, "Forbidden", http.StatusForbidden)
+}
+
+func missingScopes(required, granted []string) []string {
+	grantedSet := make(map[string]bool, len(granted))
+	for _, s := range granted {
+		grantedSet[s] = true
+	}
+
+	var missing []string
+	for _, s := range required {
+		if !grantedSet[s] {
+			missing = append(missing, s)
+		}
+	}
+	return missing
+}
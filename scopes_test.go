@@ -0,0 +1,29 @@
+package tokenauth
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMissingScopes(t *testing.T) {
+	cases := []struct {
+		name     string
+		required []string
+		granted  []string
+		want     []string
+	}{
+		{"none missing", []string{"read"}, []string{"read", "write"}, nil},
+		{"one missing", []string{"read", "write"}, []string{"read"}, []string{"write"}},
+		{"all missing", []string{"read", "write"}, nil, []string{"read", "write"}},
+		{"no scopes required", nil, []string{"read"}, nil},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := missingScopes(c.required, c.granted)
+			if !reflect.DeepEqual(got, c.want) {
+				t.Fatalf("missingScopes(%v, %v) = %v, want %v", c.required, c.granted, got, c.want)
+			}
+		})
+	}
+}
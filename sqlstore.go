@@ -0,0 +1,170 @@
+package tokenauth
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// SQLTokenStore is a TokenStore backed by a sql.DB. It stores only the
+// Argon2id hash of each token (via HashArgon2id), never the raw value, in a
+// table of the following shape:
+//
+//	CREATE TABLE tokenauth_tokens (
+//		lookup_key   TEXT PRIMARY KEY,
+//		hash         TEXT NOT NULL,
+//		user_id      TEXT NOT NULL,
+//		scopes       TEXT NOT NULL,
+//		issued_at    TIMESTAMP NOT NULL,
+//		expires_at   TIMESTAMP NOT NULL,
+//		last_used_at TIMESTAMP,
+//		revoked      BOOLEAN NOT NULL DEFAULT FALSE
+//	);
+//
+// Scopes are stored as a space-separated string, mirroring the OAuth2
+// "scope" parameter convention.
+type SQLTokenStore struct {
+	DB *sql.DB
+
+	// Table overrides the table name. Optional, defaults to "tokenauth_tokens".
+	Table string
+}
+
+// NewSQLTokenStore creates a SQLTokenStore backed by db, using the default table name.
+func NewSQLTokenStore(db *sql.DB) *SQLTokenStore {
+	return &SQLTokenStore{DB: db}
+}
+
+func (s *SQLTokenStore) table() string {
+	if s.Table != "" {
+		return s.Table
+	}
+	return "tokenauth_tokens"
+}
+
+func (s *SQLTokenStore) Issue(userID string, ttl time.Duration, scopes []string) (IssuedToken, error) {
+	raw, err := New()
+	if err != nil {
+		return IssuedToken{}, err
+	}
+
+	hash, err := HashArgon2id(raw)
+	if err != nil {
+		return IssuedToken{}, err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+
+	_, err = s.DB.Exec(
+		"INSERT INTO "+s.table()+" (lookup_key, hash, user_id, scopes, issued_at, expires_at, revoked) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		lookupKey(raw), hash, userID, strings.Join(scopes, " "), now, expiresAt, false,
+	)
+	if err != nil {
+		return IssuedToken{}, err
+	}
+
+	return IssuedToken{Raw: raw, UserID: userID, Scopes: scopes, ExpiresAt: expiresAt}, nil
+}
+
+func (s *SQLTokenStore) Lookup(raw string) (*TokenInfo, error) {
+	row := s.DB.QueryRow(
+		"SELECT hash, user_id, scopes, issued_at, expires_at, revoked FROM "+s.table()+" WHERE lookup_key = ?",
+		lookupKey(raw),
+	)
+
+	var hash, userID, scopes string
+	var issuedAt, expiresAt time.Time
+	var revoked bool
+	if err := row.Scan(&hash, &userID, &scopes, &issuedAt, &expiresAt, &revoked); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrTokenNotFound
+		}
+		return nil, err
+	}
+
+	if !VerifyHash(raw, hash) {
+		return nil, ErrTokenNotFound
+	}
+	if revoked {
+		return nil, ErrTokenRevoked
+	}
+	if time.Now().After(expiresAt) {
+		return nil, ErrTokenExpired
+	}
+
+	lastUsedAt := time.Now()
+	if _, err := s.DB.Exec("UPDATE "+s.table()+" SET last_used_at = ? WHERE lookup_key = ?", lastUsedAt, lookupKey(raw)); err != nil {
+		return nil, err
+	}
+
+	return &TokenInfo{
+		UserID:     userID,
+		Scopes:     splitScopes(scopes),
+		IssuedAt:   issuedAt,
+		ExpiresAt:  expiresAt,
+		LastUsedAt: lastUsedAt,
+	}, nil
+}
+
+func (s *SQLTokenStore) Revoke(raw string) error {
+	result, err := s.DB.Exec("UPDATE "+s.table()+" SET revoked = ? WHERE lookup_key = ?", true, lookupKey(raw))
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return ErrTokenNotFound
+	}
+	return nil
+}
+
+func (s *SQLTokenStore) Rotate(raw string) (IssuedToken, error) {
+	info, err := s.Lookup(raw)
+	if err != nil {
+		return IssuedToken{}, err
+	}
+	if err := s.Revoke(raw); err != nil {
+		return IssuedToken{}, err
+	}
+	return s.Issue(info.UserID, time.Until(info.ExpiresAt), info.Scopes)
+}
+
+func (s *SQLTokenStore) ListForUser(userID string) ([]TokenInfo, error) {
+	rows, err := s.DB.Query(
+		"SELECT scopes, issued_at, expires_at, last_used_at FROM "+s.table()+" WHERE user_id = ? AND revoked = ?",
+		userID, false,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var infos []TokenInfo
+	for rows.Next() {
+		var scopes string
+		var issuedAt, expiresAt time.Time
+		var lastUsedAt sql.NullTime
+		if err := rows.Scan(&scopes, &issuedAt, &expiresAt, &lastUsedAt); err != nil {
+			return nil, err
+		}
+
+		info := TokenInfo{
+			UserID:    userID,
+			Scopes:    splitScopes(scopes),
+			IssuedAt:  issuedAt,
+			ExpiresAt: expiresAt,
+		}
+		if lastUsedAt.Valid {
+			info.LastUsedAt = lastUsedAt.Time
+		}
+		infos = append(infos, info)
+	}
+	return infos, rows.Err()
+}
+
+func splitScopes(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, " ")
+}
@@ -0,0 +1,170 @@
+package tokenauth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Errors returned by TokenStore implementations.
+var (
+	ErrTokenNotFound = errors.New("tokenauth: token not found")
+	ErrTokenExpired  = errors.New("tokenauth: token expired")
+	ErrTokenRevoked  = errors.New("tokenauth: token revoked")
+)
+
+// IssuedToken is a newly issued credential. Raw is the only field that
+// should ever be sent to the caller or over the wire; TokenStore
+// implementations never persist it, only an Argon2id hash of it.
+type IssuedToken struct {
+	Raw       string
+	UserID    string
+	Scopes    []string
+	ExpiresAt time.Time
+}
+
+// TokenInfo describes a token as held by a TokenStore.
+type TokenInfo struct {
+	UserID     string
+	Scopes     []string
+	IssuedAt   time.Time
+	ExpiresAt  time.Time
+	LastUsedAt time.Time
+	Revoked    bool
+}
+
+// TokenStore manages the full lifecycle of issued tokens: issuing, looking
+// up, revoking, rotating, and listing them. AuthTokenMiddleware can use a
+// TokenStore in place of Authenticator to authenticate requests directly
+// against it.
+type TokenStore interface {
+	// Issue mints a new token for userID, valid for ttl, and persists it.
+	Issue(userID string, ttl time.Duration, scopes []string) (IssuedToken, error)
+
+	// Lookup resolves raw to the TokenInfo it was issued with. It returns
+	// ErrTokenNotFound, ErrTokenExpired, or ErrTokenRevoked as appropriate.
+	Lookup(raw string) (*TokenInfo, error)
+
+	// Revoke invalidates raw so future Lookup calls fail.
+	Revoke(raw string) error
+
+	// Rotate revokes raw and issues a new token with the same userID and
+	// scopes, preserving its remaining TTL.
+	Rotate(raw string) (IssuedToken, error)
+
+	// ListForUser returns every non-revoked token issued to userID.
+	ListForUser(userID string) ([]TokenInfo, error)
+}
+
+// lookupKey is a deterministic index derived from a raw token, used by
+// TokenStore implementations to locate a row/entry without storing the raw
+// token itself. It only narrows the search to a candidate entry; VerifyHash
+// against that entry's Argon2id hash is what actually authenticates it.
+func lookupKey(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+type memoryEntry struct {
+	hash string
+	info TokenInfo
+}
+
+// MemoryTokenStore is an in-memory TokenStore, suitable for tests and
+// single-process deployments. It is safe for concurrent use.
+type MemoryTokenStore struct {
+	mu      sync.Mutex
+	entries map[string]*memoryEntry
+}
+
+// NewMemoryTokenStore creates an empty MemoryTokenStore.
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{entries: make(map[string]*memoryEntry)}
+}
+
+func (s *MemoryTokenStore) Issue(userID string, ttl time.Duration, scopes []string) (IssuedToken, error) {
+	raw, err := New()
+	if err != nil {
+		return IssuedToken{}, err
+	}
+
+	hash, err := HashArgon2id(raw)
+	if err != nil {
+		return IssuedToken{}, err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+
+	s.mu.Lock()
+	s.entries[lookupKey(raw)] = &memoryEntry{
+		hash: hash,
+		info: TokenInfo{
+			UserID:    userID,
+			Scopes:    scopes,
+			IssuedAt:  now,
+			ExpiresAt: expiresAt,
+		},
+	}
+	s.mu.Unlock()
+
+	return IssuedToken{Raw: raw, UserID: userID, Scopes: scopes, ExpiresAt: expiresAt}, nil
+}
+
+func (s *MemoryTokenStore) Lookup(raw string) (*TokenInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[lookupKey(raw)]
+	if !ok || !VerifyHash(raw, entry.hash) {
+		return nil, ErrTokenNotFound
+	}
+	if entry.info.Revoked {
+		return nil, ErrTokenRevoked
+	}
+	if time.Now().After(entry.info.ExpiresAt) {
+		return nil, ErrTokenExpired
+	}
+
+	entry.info.LastUsedAt = time.Now()
+	info := entry.info
+	return &info, nil
+}
+
+func (s *MemoryTokenStore) Revoke(raw string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[lookupKey(raw)]
+	if !ok {
+		return ErrTokenNotFound
+	}
+	entry.info.Revoked = true
+	return nil
+}
+
+func (s *MemoryTokenStore) Rotate(raw string) (IssuedToken, error) {
+	info, err := s.Lookup(raw)
+	if err != nil {
+		return IssuedToken{}, err
+	}
+	if err := s.Revoke(raw); err != nil {
+		return IssuedToken{}, err
+	}
+	return s.Issue(info.UserID, time.Until(info.ExpiresAt), info.Scopes)
+}
+
+func (s *MemoryTokenStore) ListForUser(userID string) ([]TokenInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var infos []TokenInfo
+	for _, entry := range s.entries {
+		if entry.info.UserID == userID && !entry.info.Revoked {
+			infos = append(infos, entry.info)
+		}
+	}
+	return infos, nil
+}
@@ -0,0 +1,70 @@
+package tokenauth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryTokenStoreRevoke(t *testing.T) {
+	store := NewMemoryTokenStore()
+
+	issued, err := store.Issue("user-1", time.Hour, []string{"read"})
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+
+	info, err := store.Lookup(issued.Raw)
+	if err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+	if info.UserID != "user-1" {
+		t.Fatalf("UserID = %q, want %q", info.UserID, "user-1")
+	}
+
+	if err := store.Revoke(issued.Raw); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	if _, err := store.Lookup(issued.Raw); err != ErrTokenRevoked {
+		t.Fatalf("Lookup after revoke = %v, want ErrTokenRevoked", err)
+	}
+}
+
+func TestMemoryTokenStoreExpiry(t *testing.T) {
+	store := NewMemoryTokenStore()
+
+	issued, err := store.Issue("user-1", -time.Minute, nil)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+
+	if _, err := store.Lookup(issued.Raw); err != ErrTokenExpired {
+		t.Fatalf("Lookup of an expired token = %v, want ErrTokenExpired", err)
+	}
+}
+
+func TestMemoryTokenStoreRotate(t *testing.T) {
+	store := NewMemoryTokenStore()
+
+	issued, err := store.Issue("user-1", time.Hour, []string{"read"})
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+
+	rotated, err := store.Rotate(issued.Raw)
+	if err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	if rotated.Raw == issued.Raw {
+		t.Fatalf("Rotate returned the same raw token")
+	}
+	if rotated.UserID != "user-1" {
+		t.Fatalf("rotated UserID = %q, want %q", rotated.UserID, "user-1")
+	}
+
+	if _, err := store.Lookup(issued.Raw); err != ErrTokenRevoked {
+		t.Fatalf("Lookup of the pre-rotation token = %v, want ErrTokenRevoked", err)
+	}
+	if _, err := store.Lookup(rotated.Raw); err != nil {
+		t.Fatalf("Lookup of the rotated token: %v", err)
+	}
+}
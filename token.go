@@ -1,7 +1,6 @@
 package tokenauth
 
 import (
-	"crypto/md5"
 	"crypto/rand"
 	"crypto/subtle"
 	"encoding/base64"
@@ -10,6 +9,8 @@ import (
 	"net/http"
 	"strings"
 
+	jwt "github.com/dgrijalva/jwt-go"
+
 	"github.com/ant0ine/go-json-rest/rest"
 )
 
@@ -22,14 +23,56 @@ type AuthTokenMiddleware struct {
 	Realm string
 
 	// Callback function that should perform the authentication of the user based on token.
-	// Must return userID as string on success, empty string on failure. Required.
-	// The returned userID is normally the primary key for your user record.
+	// Must return userID as string on success, empty string on failure. Required,
+	// unless Store or AuthenticatorClaims is set instead.
 	Authenticator func(token string) string
 
+	// Store, if set, is used instead of Authenticator to authenticate opaque
+	// tokens directly against a TokenStore: REMOTE_USER and TOKEN_SCOPES are
+	// populated from the looked-up TokenInfo, and expired/revoked tokens are
+	// rejected automatically.
+	Store TokenStore
+
 	// Callback function that should perform the authorization of the authenticated user.
 	// Must return true on success, false on failure. Optional, defaults to success.
 	// Called only after an authentication success.
 	Authorizer func(request *rest.Request) bool
+
+	// TokenScheme selects which Authorization scheme(s) are accepted: SchemeToken
+	// (default), SchemeBearer, or SchemeAuto to accept either. Optional.
+	TokenScheme TokenScheme
+
+	// JWTKeyFunc resolves the key used to verify a JWT's signature, as in
+	// dgrijalva/jwt-go. Required when TokenScheme is SchemeBearer or SchemeAuto.
+	JWTKeyFunc func(token *jwt.Token) (interface{}, error)
+
+	// Claims builds the concrete jwt.Claims value that an incoming JWT is
+	// unmarshalled into. Optional, defaults to jwt.MapClaims.
+	Claims func() jwt.Claims
+
+	// AuthenticatorClaims is the JWT counterpart to Authenticator: it receives
+	// the verified claims of a Bearer token and returns the userID, without
+	// requiring a database lookup. Required when JWTKeyFunc is set.
+	AuthenticatorClaims func(claims jwt.Claims) string
+
+	// Issuer, if set, is required to match the "iss" claim of an incoming JWT.
+	Issuer string
+
+	// Audience, if set, is required to be present in the "aud" claim of an
+	// incoming JWT.
+	Audience string
+
+	// Extractors is the ordered chain of TokenExtractors tried against each
+	// request; the first one to report ok is used. Optional, defaults to a
+	// chain that preserves this package's historical behavior: the
+	// "?access_token=" query parameter, then the Authorization header.
+	Extractors []TokenExtractor
+
+	// Challenges lists the schemes advertised via WWW-Authenticate when
+	// authentication fails, each rendered as its own header per RFC 7235.
+	// Optional, defaults to TokenScheme (or both "Token" and "Bearer" for
+	// SchemeAuto).
+	Challenges []string
 }
 
 // MiddlewareFunc makes AuthTokenMiddleware implement the Middleware interface.
@@ -38,8 +81,8 @@ func (mw *AuthTokenMiddleware) MiddlewareFunc(handler rest.HandlerFunc) rest.Han
 		log.Fatal("Realm is required")
 	}
 
-	if mw.Authenticator == nil {
-		log.Fatal("Authenticator is required")
+	if mw.Authenticator == nil && mw.AuthenticatorClaims == nil && mw.Store == nil {
+		log.Fatal("Authenticator, AuthenticatorClaims, or Store is required")
 	}
 
 	if mw.Authorizer == nil {
@@ -48,28 +91,55 @@ func (mw *AuthTokenMiddleware) MiddlewareFunc(handler rest.HandlerFunc) rest.Han
 		}
 	}
 
-	return func(writer rest.ResponseWriter, request *rest.Request) {
-		var err error
-		var token string
-		if len(request.URL.Query().Get("access_token")) > 0 {
-			token = request.URL.Query().Get("access_token")
+	if mw.TokenScheme == "" {
+		mw.TokenScheme = SchemeToken
+	}
+
+	if mw.Extractors == nil {
+		mw.Extractors = []TokenExtractor{
+			QueryTokenExtractor("access_token", SchemeToken),
+			HeaderTokenExtractor(),
+		}
+	}
+
+	if mw.Challenges == nil {
+		if mw.TokenScheme == SchemeAuto {
+			mw.Challenges = []string{string(SchemeToken), string(SchemeBearer)}
 		} else {
-			authHeader := request.Header.Get("Authorization")
-			// Authorization header was not provided
-			if authHeader == "" {
-				mw.unauthorized(writer)
-				return
-			}
+			mw.Challenges = []string{string(mw.TokenScheme)}
+		}
+	}
 
-			token, err = decodeAuthHeader(authHeader)
-			// Authorization header was *malformed* such that we couldn't extract a token
-			if err != nil {
-				mw.unauthorized(writer)
-				return
+	return func(writer rest.ResponseWriter, request *rest.Request) {
+		var scheme, token string
+		var found bool
+		for _, extract := range mw.Extractors {
+			if scheme, token, found = extract(request); found {
+				break
 			}
 		}
+		if !found {
+			mw.unauthorized(writer)
+			return
+		}
+
+		isTokenFamily := scheme != string(SchemeBearer) && (mw.TokenScheme == SchemeToken || mw.TokenScheme == SchemeAuto)
 
-		userID := mw.Authenticator(token)
+		var userID string
+		var scopes []string
+		switch {
+		case scheme == string(SchemeBearer) && (mw.TokenScheme == SchemeBearer || mw.TokenScheme == SchemeAuto):
+			userID = mw.authenticateJWT(token)
+		case isTokenFamily && mw.Store != nil:
+			if info, err := mw.Store.Lookup(token); err == nil {
+				userID, scopes = info.UserID, info.Scopes
+			}
+		case isTokenFamily && mw.Authenticator != nil:
+			userID = mw.Authenticator(token)
+		default:
+			mw.unauthorized(writer)
+			return
+		}
 		// The token didn't map to a user, it's most likely either invalid or expired
 		if userID == "" {
 			mw.unauthorized(writer)
@@ -83,26 +153,39 @@ func (mw *AuthTokenMiddleware) MiddlewareFunc(handler rest.HandlerFunc) rest.Han
 		}
 
 		request.Env["REMOTE_USER"] = userID
+		if scopes != nil {
+			request.Env["TOKEN_SCOPES"] = scopes
+		}
 		handler(writer, request)
 	}
 }
 
+// unauthorized emits one WWW-Authenticate header per configured challenge
+// scheme, per RFC 7235, so the client can pick whichever it supports.
 func (mw *AuthTokenMiddleware) unauthorized(writer rest.ResponseWriter) {
-	writer.Header().Set("WWW-Authenticate", "Token realm="+mw.Realm)
+	for _, scheme := range mw.Challenges {
+		writer.Header().Add("WWW-Authenticate", formatChallenge(scheme, mw.Realm))
+	}
 	rest.Error(writer, "Not Authorized", http.StatusUnauthorized)
 }
 
-// Extract the token from an Authorization header
-func decodeAuthHeader(header string) (string, error) {
+// Extract the scheme and raw token from an Authorization header, e.g.
+// "Token abc123" -> ("Token", "abc123"). The Token scheme is additionally
+// validated as URL-safe base64, matching what New() generates; other
+// schemes (e.g. Bearer) are returned as-is for their own verification.
+func decodeAuthHeader(header string) (scheme, token string, err error) {
 	parts := strings.SplitN(header, " ", 2)
-	if !(len(parts) == 2 && parts[0] == "Token") {
-		return "", errors.New("Invalid Authorization header")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", errors.New("Invalid Authorization header")
 	}
-	_, err := base64.URLEncoding.DecodeString(parts[1])
-	if err != nil {
-		return "", errors.New("Token encoding not valid")
+
+	if parts[0] == string(SchemeToken) {
+		if _, err := base64.URLEncoding.DecodeString(parts[1]); err != nil {
+			return "", "", errors.New("Token encoding not valid")
+		}
 	}
-	return string(parts[1]), nil
+
+	return parts[0], parts[1], nil
 }
 
 // New generates a new random token
@@ -120,14 +203,9 @@ func Equal(a, b string) bool {
 	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
 }
 
-// Hash applies a simple MD5 hash over a token, making it safe to store
-func Hash(token string) string {
-	hashed := md5.Sum([]byte(token))
-	return base64.URLEncoding.EncodeToString(hashed[:])
-}
-
 // Token extracts current request's token, useful for logout and refresh where it's used post-auth
 func Token(request *rest.Request) (string, error) {
 	authHeader := request.Header.Get("Authorization")
-	return decodeAuthHeader(authHeader)
+	_, token, err := decodeAuthHeader(authHeader)
+	return token, err
 }